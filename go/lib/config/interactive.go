@@ -0,0 +1,195 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// Prompter is a terminal abstraction: it writes prompts and reads back a
+// single line of user input. RunInteractive uses it to drive the walk
+// over a Config tree; ScriptedPrompter lets tests replay canned answers
+// without a real terminal.
+type Prompter interface {
+	// Prompt writes prompt, then reads and returns a line of input with
+	// its trailing newline stripped. An empty line means "keep the
+	// default".
+	Prompt(prompt string) (string, error)
+}
+
+// terminalPrompter is the Prompter used by RunInteractive.
+type terminalPrompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+func newTerminalPrompter(in io.Reader, out io.Writer) *terminalPrompter {
+	return &terminalPrompter{in: bufio.NewScanner(in), out: out}
+}
+
+func (p *terminalPrompter) Prompt(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	if !p.in.Scan() {
+		if err := p.in.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return p.in.Text(), nil
+}
+
+// ScriptedPrompter is a Prompter that returns Answers in order, ignoring the
+// prompt text, for use in tests. Once Answers is exhausted it returns "".
+type ScriptedPrompter struct {
+	Answers []string
+	pos     int
+}
+
+// Prompt implements Prompter.
+func (p *ScriptedPrompter) Prompt(_ string) (string, error) {
+	if p.pos >= len(p.Answers) {
+		return "", nil
+	}
+	answer := p.Answers[p.pos]
+	p.pos++
+	return answer, nil
+}
+
+// RunInteractive interactively edits cfg: it walks the config tree rooted at
+// cfg, presenting the current (default) value of every leaf field and
+// accepting an edited value from in/out, then validates and writes the
+// resulting TOML to out.
+//
+// cfg must be a pointer to a struct implementing Config, already having had
+// InitDefaults called (or not; RunInteractive calls it first to ensure every
+// field starts from a sane default).
+func RunInteractive(cfg Config, in io.Reader, out io.Writer) error {
+	cfg.InitDefaults()
+	prompter := newTerminalPrompter(in, out)
+	if err := walk(reflect.ValueOf(cfg), nil, prompter); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return common.NewBasicError("configured values do not validate", err)
+	}
+	WriteConfiguration(out, nil, nil, cfg)
+	return nil
+}
+
+// walk recurses through v (the value backing a Config, or a field of one),
+// prompting for every scalar field it finds. Struct and pointer fields are
+// recursed into, whether or not they implement Config; slice, array and map
+// fields are skipped. walk itself does not call Validate.
+func walk(v reflect.Value, path Path, p Prompter) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		fieldVal := v.Field(i)
+		fieldPath := path.Extend(field.Name)
+
+		switch fieldVal.Kind() {
+		case reflect.Struct:
+			if err := walk(fieldVal, fieldPath, p); err != nil {
+				return err
+			}
+		case reflect.Ptr:
+			if err := walk(fieldVal, fieldPath, p); err != nil {
+				return err
+			}
+		case reflect.Slice, reflect.Array, reflect.Map:
+			// Not editable as a single-line scalar answer; leave it at
+			// whatever InitDefaults set it to instead of aborting the
+			// whole session.
+			continue
+		default:
+			if err := promptField(fieldVal, fieldPath, p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// promptField prompts for a single leaf field, showing its current
+// (default) value, and sets the field from the user's answer. An empty
+// answer keeps the default.
+func promptField(v reflect.Value, path Path, p Prompter) error {
+	if !v.CanSet() {
+		return nil
+	}
+	answer, err := p.Prompt(fmt.Sprintf("%s [%v]: ", strings.Join(path, "."), v.Interface()))
+	if err != nil {
+		return common.NewBasicError("reading answer", err, "field", path)
+	}
+	if answer == "" {
+		return nil
+	}
+	return setScalar(v, answer, path)
+}
+
+func setScalar(v reflect.Value, answer string, path Path) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(answer)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(answer)
+		if err != nil {
+			return common.NewBasicError("invalid bool value", err, "field", path, "value", answer)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(answer, 10, 64)
+		if err != nil {
+			return common.NewBasicError("invalid integer value", err, "field", path, "value", answer)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(answer, 10, 64)
+		if err != nil {
+			return common.NewBasicError("invalid unsigned integer value", err,
+				"field", path, "value", answer)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(answer, 64)
+		if err != nil {
+			return common.NewBasicError("invalid float value", err, "field", path, "value", answer)
+		}
+		v.SetFloat(f)
+	default:
+		return common.NewBasicError("unsupported field type for interactive configuration", nil,
+			"field", path, "kind", v.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sampleConfig struct {
+	NoValidator
+	Name  string
+	Count int
+	Tags  []string
+	Extra map[string]string
+}
+
+func (c *sampleConfig) InitDefaults() {
+	if c.Name == "" {
+		c.Name = "default"
+	}
+	if c.Tags == nil {
+		c.Tags = []string{"a", "b"}
+	}
+}
+
+func (c *sampleConfig) Sample(dst io.Writer, _ Path, _ CtxMap)    {}
+func (c *sampleConfig) ConfigName() string                        { return "sample" }
+func (c *sampleConfig) Configure(dst io.Writer, _ Path, _ CtxMap) {}
+
+func TestWalkSkipsSliceAndMapFields(t *testing.T) {
+	cfg := &sampleConfig{}
+	cfg.InitDefaults()
+
+	prompter := &ScriptedPrompter{Answers: []string{"renamed", "7"}}
+	err := walk(reflect.ValueOf(cfg), nil, prompter)
+	require.NoError(t, err)
+
+	assert.Equal(t, "renamed", cfg.Name)
+	assert.Equal(t, 7, cfg.Count)
+	// Slice/map fields are left untouched rather than aborting the walk.
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Nil(t, cfg.Extra)
+}
+
+func TestScriptedPrompterEmptyAnswerKeepsDefault(t *testing.T) {
+	cfg := &sampleConfig{}
+	cfg.InitDefaults()
+
+	prompter := &ScriptedPrompter{Answers: []string{""}}
+	require.NoError(t, walk(reflect.ValueOf(cfg), nil, prompter))
+	assert.Equal(t, "default", cfg.Name)
+}
@@ -0,0 +1,141 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crypto implements the Sign/Verify primitives used by
+// proto.SignS, one Algorithm per supported SignType.
+package crypto
+
+import (
+	stdcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// Algorithm identifies a signing/verification algorithm.
+type Algorithm int
+
+const (
+	Ed25519 Algorithm = iota
+	EcdsaP256Sha256
+	EcdsaP384Sha384
+	RsaPssSha256
+)
+
+// Sign signs msg with key using algo. For Ed25519, key is the raw 32-byte
+// seed; for the other algorithms, key is a PKCS#8 DER-encoded private key.
+func Sign(msg, key common.RawBytes, algo Algorithm) (common.RawBytes, error) {
+	switch algo {
+	case Ed25519:
+		return ed25519.Sign(ed25519.NewKeyFromSeed(key), msg), nil
+	case EcdsaP256Sha256:
+		return signECDSA(msg, key, stdcrypto.SHA256)
+	case EcdsaP384Sha384:
+		return signECDSA(msg, key, stdcrypto.SHA384)
+	case RsaPssSha256:
+		return signRSAPSS(msg, key)
+	default:
+		return nil, common.NewBasicError("crypto: unsupported algorithm", nil, "algo", algo)
+	}
+}
+
+// Verify checks sig over msg under key, using algo. Key encoding mirrors
+// Sign: the raw Ed25519 public key, or a PKIX DER-encoded public key for the
+// other algorithms.
+func Verify(msg, sig, key common.RawBytes, algo Algorithm) error {
+	switch algo {
+	case Ed25519:
+		if !ed25519.Verify(ed25519.PublicKey(key), msg, sig) {
+			return common.NewBasicError("crypto: invalid Ed25519 signature", nil)
+		}
+		return nil
+	case EcdsaP256Sha256:
+		return verifyECDSA(msg, sig, key, stdcrypto.SHA256)
+	case EcdsaP384Sha384:
+		return verifyECDSA(msg, sig, key, stdcrypto.SHA384)
+	case RsaPssSha256:
+		return verifyRSAPSS(msg, sig, key)
+	default:
+		return common.NewBasicError("crypto: unsupported algorithm", nil, "algo", algo)
+	}
+}
+
+func signECDSA(msg, key common.RawBytes, hash stdcrypto.Hash) (common.RawBytes, error) {
+	priv, err := x509.ParsePKCS8PrivateKey(key)
+	if err != nil {
+		return nil, common.NewBasicError("crypto: parsing ECDSA private key", err)
+	}
+	ecdsaKey, ok := priv.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, common.NewBasicError("crypto: key is not an ECDSA private key", nil)
+	}
+	digest := digestWith(hash, msg)
+	return ecdsa.SignASN1(rand.Reader, ecdsaKey, digest)
+}
+
+func verifyECDSA(msg, sig, key common.RawBytes, hash stdcrypto.Hash) error {
+	pub, err := x509.ParsePKIXPublicKey(key)
+	if err != nil {
+		return common.NewBasicError("crypto: parsing ECDSA public key", err)
+	}
+	ecdsaKey, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return common.NewBasicError("crypto: key is not an ECDSA public key", nil)
+	}
+	digest := digestWith(hash, msg)
+	if !ecdsa.VerifyASN1(ecdsaKey, digest, sig) {
+		return common.NewBasicError("crypto: invalid ECDSA signature", nil)
+	}
+	return nil
+}
+
+func digestWith(hash stdcrypto.Hash, msg []byte) []byte {
+	h := hash.New()
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+func signRSAPSS(msg, key common.RawBytes) (common.RawBytes, error) {
+	priv, err := x509.ParsePKCS8PrivateKey(key)
+	if err != nil {
+		return nil, common.NewBasicError("crypto: parsing RSA private key", err)
+	}
+	rsaKey, ok := priv.(*rsa.PrivateKey)
+	if !ok {
+		return nil, common.NewBasicError("crypto: key is not an RSA private key", nil)
+	}
+	digest := sha256.Sum256(msg)
+	return rsa.SignPSS(rand.Reader, rsaKey, stdcrypto.SHA256, digest[:], nil)
+}
+
+func verifyRSAPSS(msg, sig, key common.RawBytes) error {
+	pub, err := x509.ParsePKIXPublicKey(key)
+	if err != nil {
+		return common.NewBasicError("crypto: parsing RSA public key", err)
+	}
+	rsaKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return common.NewBasicError("crypto: key is not an RSA public key", nil)
+	}
+	digest := sha256.Sum256(msg)
+	if err := rsa.VerifyPSS(rsaKey, stdcrypto.SHA256, digest[:], sig, nil); err != nil {
+		return common.NewBasicError("crypto: invalid RSA-PSS signature", err)
+	}
+	return nil
+}
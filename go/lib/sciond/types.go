@@ -16,11 +16,13 @@ package sciond
 
 import (
 	"fmt"
+	"net"
 	"strings"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
+	"github.com/scionproto/scion/go/lib/scmp"
 	"github.com/scionproto/scion/go/proto"
 )
 
@@ -31,6 +33,10 @@ const (
 	ErrorNoPaths
 	ErrorPSTimeout
 	ErrorInternal
+	// ErrorHiddenPathsUnavailable is returned when a PathReq sets
+	// Flags.Hidden but the resolver has no hidden-path configuration to
+	// serve the request from.
+	ErrorHiddenPathsUnavailable
 )
 
 func (c PathErrorCode) String() string {
@@ -43,6 +49,8 @@ func (c PathErrorCode) String() string {
 		return "SCIOND timed out while requesting paths"
 	case ErrorInternal:
 		return "SCIOND experienced an internal error"
+	case ErrorHiddenPathsUnavailable:
+		return "No hidden-path configuration available"
 	default:
 		return fmt.Sprintf("Unknown error (%v)", uint16(c))
 	}
@@ -51,18 +59,26 @@ func (c PathErrorCode) String() string {
 var _ proto.Cerealizable = (*Pld)(nil)
 
 type Pld struct {
-	Id                 uint64
-	Which              proto.SCIONDMsg_Which
-	PathReq            PathReq
-	PathReply          PathReply
-	AsInfoReq          ASInfoReq
-	AsInfoReply        ASInfoReply
-	RevNotification    RevNotification
-	RevReply           RevReply
-	IfInfoRequest      IFInfoRequest
-	IfInfoReply        IFInfoReply
-	ServiceInfoRequest ServiceInfoRequest
-	ServiceInfoReply   ServiceInfoReply
+	Id                      uint64
+	Which                   proto.SCIONDMsg_Which
+	PathReq                 PathReq
+	PathReply               PathReply
+	AsInfoReq               ASInfoReq
+	AsInfoReply             ASInfoReply
+	RevNotification         RevNotification
+	RevReply                RevReply
+	IfInfoRequest           IFInfoRequest
+	IfInfoReply             IFInfoReply
+	ServiceInfoRequest      ServiceInfoRequest
+	ServiceInfoReply        ServiceInfoReply
+	EndhostPortRangeRequest EndhostPortRangeRequest
+	EndhostPortRangeReply   EndhostPortRangeReply
+	ScmpRegisterRequest     SCMPRegisterRequest
+	ScmpRegisterReply       SCMPRegisterReply
+	ScmpUnregisterRequest   SCMPUnregisterRequest
+	ScmpUnregisterReply     SCMPUnregisterReply
+	ScmpKeepalive           SCMPKeepalive
+	ScmpNotification        SCMPNotification
 }
 
 func NewPldFromRaw(b common.RawBytes) (*Pld, error) {
@@ -107,6 +123,22 @@ func (p *Pld) union() (interface{}, error) {
 		return p.ServiceInfoRequest, nil
 	case proto.SCIONDMsg_Which_serviceInfoReply:
 		return p.ServiceInfoReply, nil
+	case proto.SCIONDMsg_Which_endhostPortRangeRequest:
+		return p.EndhostPortRangeRequest, nil
+	case proto.SCIONDMsg_Which_endhostPortRangeReply:
+		return p.EndhostPortRangeReply, nil
+	case proto.SCIONDMsg_Which_scmpRegisterRequest:
+		return p.ScmpRegisterRequest, nil
+	case proto.SCIONDMsg_Which_scmpRegisterReply:
+		return p.ScmpRegisterReply, nil
+	case proto.SCIONDMsg_Which_scmpUnregisterRequest:
+		return p.ScmpUnregisterRequest, nil
+	case proto.SCIONDMsg_Which_scmpUnregisterReply:
+		return p.ScmpUnregisterReply, nil
+	case proto.SCIONDMsg_Which_scmpKeepalive:
+		return p.ScmpKeepalive, nil
+	case proto.SCIONDMsg_Which_scmpNotification:
+		return p.ScmpNotification, nil
 	}
 	return nil, common.NewBasicError("Unsupported SCIOND union type", nil, "type", p.Which)
 }
@@ -116,11 +148,21 @@ type PathReq struct {
 	Src      addr.IAInt
 	MaxPaths uint16
 	Flags    PathReqFlags
+	// HiddenPathGroups restricts the reply to segments belonging to one of
+	// these hidden-path groups. It is only consulted when Flags.Hidden is
+	// set; an empty slice with Flags.Hidden set means "any group the
+	// resolver is configured for".
+	HiddenPathGroups []uint64
 }
 
 type PathReqFlags struct {
 	Flush bool
 	Sibra bool
+	// Hidden requests paths belonging to a hidden-path group, instead of
+	// (or in addition to, depending on resolver policy) publicly
+	// registered segments. See PathReq.HiddenPathGroups to narrow the
+	// request to specific groups.
+	Hidden bool
 }
 
 type PathReply struct {
@@ -131,6 +173,12 @@ type PathReply struct {
 type PathReplyEntry struct {
 	Path     FwdPathMeta
 	HostInfo HostInfo
+	// Hidden indicates that Path was resolved from a hidden-path group
+	// rather than the public path store.
+	Hidden bool
+	// HiddenPathGroup is the group Path was resolved from. It is only
+	// meaningful when Hidden is true.
+	HiddenPathGroup uint64
 }
 
 type HostInfo struct {
@@ -143,18 +191,49 @@ type HostInfo struct {
 
 func HostInfoFromHostAddr(host addr.HostAddr, port uint16) *HostInfo {
 	h := &HostInfo{Port: port}
-	if host.Type() == addr.HostTypeIPv4 {
-		h.Addrs.Ipv4 = host.IP()
+	ip := host.IP()
+	if host.Type() == addr.HostTypeIPv4 || isIPv4MappedIPv6(ip) {
+		h.Addrs.Ipv4 = unmapIPv4(ip)
 	} else {
-		h.Addrs.Ipv6 = host.IP()
+		h.Addrs.Ipv6 = ip
 	}
 	return h
 }
 
+// isIPv4MappedIPv6 reports whether ip is a 16-byte address in the
+// "::ffff:a.b.c.d" IPv4-mapped form. Go's resolver frequently returns
+// addresses in this form even when the underlay is plain IPv4; if such an
+// address is serialized as-is into Addrs.Ipv6, border routers see a v6
+// packet for what is actually a v4 underlay, breaking forwarding.
+func isIPv4MappedIPv6(ip []byte) bool {
+	if len(ip) != 16 {
+		return false
+	}
+	for _, b := range ip[:10] {
+		if b != 0 {
+			return false
+		}
+	}
+	return ip[10] == 0xff && ip[11] == 0xff
+}
+
+// unmapIPv4 reduces an IPv4-mapped IPv6 address (one that satisfies
+// isIPv4MappedIPv6) to its 4-byte form. Any other address is returned
+// unchanged.
+func unmapIPv4(ip []byte) []byte {
+	if isIPv4MappedIPv6(ip) {
+		return append([]byte(nil), ip[12:16]...)
+	}
+	return ip
+}
+
 func (h *HostInfo) Host() addr.HostAddr {
 	if len(h.Addrs.Ipv4) > 0 {
 		return addr.HostIPv4(h.Addrs.Ipv4)
 	}
+	if isIPv4MappedIPv6(h.Addrs.Ipv6) {
+		return addr.HostIPv4(unmapIPv4(h.Addrs.Ipv6))
+	}
 	return addr.HostIPv6(h.Addrs.Ipv6)
 }
 
@@ -281,6 +360,11 @@ type IFInfoReplyEntry struct {
 
 type ServiceInfoRequest struct {
 	ServiceTypes []ServiceType
+	// WithPortRange requests that, if the AS has moved to the stateless
+	// dispatcher model (no standalone dispatcher process), each returned
+	// ServiceInfoReplyEntry also carries the underlay UDP port range end
+	// hosts must bind within to receive SCION traffic for that service.
+	WithPortRange bool
 }
 
 type ServiceType uint16
@@ -318,4 +402,113 @@ type ServiceInfoReplyEntry struct {
 	ServiceType ServiceType
 	Ttl         uint32
 	HostInfos   []HostInfo
+	// PortRange is the underlay UDP port range end hosts must bind within
+	// to receive SCION traffic for ServiceType without a standalone
+	// dispatcher. It is only populated if the request set WithPortRange
+	// and the AS advertises a port range.
+	PortRange PortRange
+}
+
+// PortRange is an inclusive range of underlay UDP ports. The zero value
+// (Lower == Upper == 0) means "no port range advertised", since port 0 is
+// never a valid bind target.
+type PortRange struct {
+	Lower uint16
+	Upper uint16
+}
+
+// IsSet reports whether the port range was actually advertised by SCIOND.
+func (pr PortRange) IsSet() bool {
+	return pr.Lower != 0 || pr.Upper != 0
+}
+
+// Contains reports whether port lies within the advertised range.
+func (pr PortRange) Contains(port uint16) bool {
+	return pr.IsSet() && port >= pr.Lower && port <= pr.Upper
+}
+
+// EndhostPortRangeRequest asks SCIOND for the AS-wide underlay UDP port
+// range end hosts must bind within to receive SCION traffic directly from
+// border routers, without going through a standalone dispatcher.
+type EndhostPortRangeRequest struct{}
+
+// EndhostPortRangeReply is the reply to an EndhostPortRangeRequest.
+type EndhostPortRangeReply struct {
+	ErrorCode PathErrorCode
+	Range     PortRange
+}
+
+// PickSourcePort returns the first free port in pr by probing
+// net.ListenUDP at each candidate port in turn, starting from pr.Lower. It
+// returns 0 if pr is not set or no port in the range could be bound.
+func PickSourcePort(pr PortRange) uint16 {
+	if !pr.IsSet() {
+		return 0
+	}
+	for port := pr.Lower; ; port++ {
+		if probeUDPPort(port) {
+			return port
+		}
+		if port == pr.Upper {
+			break
+		}
+	}
+	return 0
+}
+
+func probeUDPPort(port uint16) bool {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: int(port)})
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// SCMPRegisterRequest subscribes the caller to asynchronous delivery of
+// SCMP errors affecting flows from Host, for any of Types. It replaces the
+// direct SCMP delivery path the reliable socket used to provide before the
+// dispatcher was removed: applications that want SCMP notifications must
+// now register for them explicitly via SCIOND.
+type SCMPRegisterRequest struct {
+	IsdAs addr.IAInt `capnp:"isdas"`
+	Host  HostInfo
+	Types []scmp.Type
+}
+
+// SCMPRegisterReply is the reply to a SCMPRegisterRequest.
+type SCMPRegisterReply struct {
+	SubId     uint64
+	ErrorCode PathErrorCode
+}
+
+// SCMPUnregisterRequest cancels a subscription previously created with
+// SCMPRegisterRequest.
+type SCMPUnregisterRequest struct {
+	SubId uint64
+}
+
+// SCMPUnregisterReply is the reply to a SCMPUnregisterRequest.
+type SCMPUnregisterReply struct {
+	ErrorCode PathErrorCode
+}
+
+// SCMPKeepalive is sent periodically by a subscriber to let SCIOND know the
+// subscription identified by SubId is still wanted; SCIOND garbage-collects
+// subscriptions that have not been kept alive for some implementation-defined
+// interval.
+type SCMPKeepalive struct {
+	SubId uint64
+}
+
+// SCMPNotification is pushed by SCIOND, unprompted, to a subscriber whenever
+// an SCMP message matching one of its registered Types arrives for its flow.
+type SCMPNotification struct {
+	SubId uint64
+	// OriginalHdr is the (start of the) packet that triggered the SCMP
+	// message, as included in the SCMP payload.
+	OriginalHdr common.RawBytes
+	Type        scmp.Type
+	Code        uint8
+	Info        common.RawBytes
 }
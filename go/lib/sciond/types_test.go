@@ -0,0 +1,130 @@
+// Copyright 2020 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sciond
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/proto"
+)
+
+func TestHostInfoFromHostAddrUnmapsIPv4MappedIPv6(t *testing.T) {
+	tests := map[string]struct {
+		Host        addr.HostAddr
+		ExpectedIP4 []byte
+		ExpectedIP6 []byte
+	}{
+		"pure v4": {
+			Host:        addr.HostIPv4(net.IPv4(10, 0, 0, 1).To4()),
+			ExpectedIP4: net.IPv4(10, 0, 0, 1).To4(),
+		},
+		"pure v6": {
+			Host:        addr.HostIPv6(net.ParseIP("2001:db8::1")),
+			ExpectedIP6: net.ParseIP("2001:db8::1"),
+		},
+		"4-in-6": {
+			Host:        addr.HostIPv6(net.ParseIP("::ffff:10.0.0.1")),
+			ExpectedIP4: net.IPv4(10, 0, 0, 1).To4(),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			hi := HostInfoFromHostAddr(test.Host, 42)
+			assert.Equal(t, net.IP(test.ExpectedIP4), net.IP(hi.Addrs.Ipv4))
+			assert.Equal(t, net.IP(test.ExpectedIP6), net.IP(hi.Addrs.Ipv6))
+		})
+	}
+}
+
+func TestHostInfoHostUnmapsIPv4MappedIPv6(t *testing.T) {
+	hi := &HostInfo{}
+	hi.Addrs.Ipv6 = net.ParseIP("::ffff:10.0.0.1")
+
+	host := hi.Host()
+	assert.Equal(t, addr.HostTypeIPv4, host.Type())
+	assert.Equal(t, net.IP(net.IPv4(10, 0, 0, 1).To4()), net.IP(host.IP()))
+}
+
+func TestPathErrorCodeHiddenPathsUnavailableString(t *testing.T) {
+	assert.Equal(t, "No hidden-path configuration available",
+		ErrorHiddenPathsUnavailable.String())
+}
+
+func TestPortRangeIsSet(t *testing.T) {
+	assert.False(t, PortRange{}.IsSet())
+	assert.True(t, PortRange{Lower: 30000, Upper: 30100}.IsSet())
+	// A single-port range is still "set" even though Lower == Upper.
+	assert.True(t, PortRange{Lower: 30000, Upper: 30000}.IsSet())
+}
+
+func TestPldUnionDispatchesScmpCases(t *testing.T) {
+	reg := SCMPRegisterRequest{IsdAs: 0x1_ff0000000110}
+	regReply := SCMPRegisterReply{SubId: 42, ErrorCode: ErrorOk}
+	unreg := SCMPUnregisterRequest{SubId: 42}
+	unregReply := SCMPUnregisterReply{ErrorCode: ErrorOk}
+	keepalive := SCMPKeepalive{SubId: 42}
+	notif := SCMPNotification{SubId: 42, OriginalHdr: common.RawBytes("hdr")}
+
+	p := &Pld{
+		ScmpRegisterRequest:   reg,
+		ScmpRegisterReply:     regReply,
+		ScmpUnregisterRequest: unreg,
+		ScmpUnregisterReply:   unregReply,
+		ScmpKeepalive:         keepalive,
+		ScmpNotification:      notif,
+	}
+
+	tests := map[string]struct {
+		Which    proto.SCIONDMsg_Which
+		Expected interface{}
+	}{
+		"register request":   {proto.SCIONDMsg_Which_scmpRegisterRequest, reg},
+		"register reply":     {proto.SCIONDMsg_Which_scmpRegisterReply, regReply},
+		"unregister request": {proto.SCIONDMsg_Which_scmpUnregisterRequest, unreg},
+		"unregister reply":   {proto.SCIONDMsg_Which_scmpUnregisterReply, unregReply},
+		"keepalive":          {proto.SCIONDMsg_Which_scmpKeepalive, keepalive},
+		"notification":       {proto.SCIONDMsg_Which_scmpNotification, notif},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p.Which = test.Which
+			got, err := p.union()
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, got)
+		})
+	}
+}
+
+func TestPldUnionRejectsUnsetWhich(t *testing.T) {
+	p := &Pld{}
+	_, err := p.union()
+	assert.Error(t, err)
+}
+
+func TestPortRangeContains(t *testing.T) {
+	pr := PortRange{Lower: 30000, Upper: 30100}
+	assert.True(t, pr.Contains(30000))
+	assert.True(t, pr.Contains(30050))
+	assert.True(t, pr.Contains(30100))
+	assert.False(t, pr.Contains(29999))
+	assert.False(t, pr.Contains(30101))
+	assert.False(t, PortRange{}.Contains(0))
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shttp
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/netsec-ethz/scion-apps/pkg/appnet/appquic"
+	"github.com/scionproto/scion/go/lib/snet"
+)
+
+// DatagramHandler handles unreliable QUIC datagrams (RFC 9221) associated
+// with the HTTP/3 request stream that established the connection. It is
+// registered on a mux path the same way an http.Handler would be, but
+// receives raw datagrams instead of a parsed request body.
+type DatagramHandler interface {
+	// ServeDatagram is called once per inbound datagram. flow carries
+	// request/connection context, including the negotiated SCION path.
+	ServeDatagram(flow *DatagramFlow, data []byte)
+}
+
+// DatagramHandlerFunc adapts a plain function to a DatagramHandler.
+type DatagramHandlerFunc func(flow *DatagramFlow, data []byte)
+
+// ServeDatagram implements DatagramHandler.
+func (f DatagramHandlerFunc) ServeDatagram(flow *DatagramFlow, data []byte) {
+	f(flow, data)
+}
+
+// DatagramFlow groups everything a DatagramHandler needs to reply and to
+// inspect or pin the SCION path the datagram arrived on.
+type DatagramFlow struct {
+	// Request is the HTTP/3 request whose stream carries the datagrams.
+	Request *http.Request
+	// Conn is the underlying HTTP/3 connection, used to send datagrams
+	// back to the peer.
+	Conn appquic.EarlyConnection
+	// Path is the SCION path stored in the request's context under
+	// appquic.PathContextKey when the flow is created. It is nil if the
+	// context carries no such path (e.g. non-SCION dialer).
+	Path snet.Path
+}
+
+// PinPath records path in f.Path. SendDatagram does not consult f.Path, so
+// pinning does not change the path used for datagrams sent on this flow;
+// that path is chosen by the underlying connection.
+func (f *DatagramFlow) PinPath(path snet.Path) {
+	f.Path = path
+}
+
+// SendDatagram sends data as an unreliable QUIC datagram on this flow's
+// connection.
+func (f *DatagramFlow) SendDatagram(data []byte) error {
+	return f.Conn.SendMessage(data)
+}
+
+// RegisterDatagramHandler associates handler with the given mux path so
+// that ListenAndServeSCIONH3 dispatches datagrams on streams opened against
+// that path to handler, in addition to normal HTTP handling done by mux.
+// Registrations are scoped to mux: two independent muxes may register
+// different handlers for the same pattern without colliding.
+func RegisterDatagramHandler(mux *http.ServeMux, pattern string, handler DatagramHandler) {
+	muxRegistriesMu.Lock()
+	defer muxRegistriesMu.Unlock()
+	reg, ok := muxRegistries[mux]
+	if !ok {
+		reg = &datagramRegistry{handlers: make(map[string]DatagramHandler)}
+		muxRegistries[mux] = reg
+	}
+	reg.mu.Lock()
+	reg.handlers[pattern] = handler
+	reg.mu.Unlock()
+}
+
+// datagramRegistry holds the DatagramHandlers registered for a single mux.
+type datagramRegistry struct {
+	mu       sync.Mutex
+	handlers map[string]DatagramHandler
+}
+
+var (
+	muxRegistriesMu sync.Mutex
+	muxRegistries   = make(map[*http.ServeMux]*datagramRegistry)
+)
+
+// datagramDispatcher wraps an *http.ServeMux, pumping datagrams for each
+// accepted request's connection to the DatagramHandler (if any) registered
+// for the request's URL path on that same mux, while handling the request
+// itself normally.
+type datagramDispatcher struct {
+	mux *http.ServeMux
+}
+
+func newDatagramDispatcher(mux *http.ServeMux) *datagramDispatcher {
+	return &datagramDispatcher{mux: mux}
+}
+
+func (d *datagramDispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	muxRegistriesMu.Lock()
+	reg := muxRegistries[d.mux]
+	muxRegistriesMu.Unlock()
+
+	var handler DatagramHandler
+	var ok bool
+	if reg != nil {
+		reg.mu.Lock()
+		handler, ok = reg.handlers[r.URL.Path]
+		reg.mu.Unlock()
+	}
+
+	if ok {
+		if conn, ok := r.Context().Value(appquic.ConnectionContextKey).(appquic.EarlyConnection); ok {
+			go pumpDatagrams(r.Context(), conn, r, handler)
+		}
+	}
+	d.mux.ServeHTTP(w, r)
+}
+
+func pumpDatagrams(ctx context.Context, conn appquic.EarlyConnection, r *http.Request,
+	handler DatagramHandler) {
+
+	flow := &DatagramFlow{Request: r, Conn: conn}
+	if p, ok := ctx.Value(appquic.PathContextKey).(snet.Path); ok {
+		flow.Path = p
+	}
+	for {
+		data, err := conn.ReceiveMessage()
+		if err != nil {
+			return
+		}
+		handler.ServeDatagram(flow, data)
+	}
+}
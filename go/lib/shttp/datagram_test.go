@@ -0,0 +1,49 @@
+// Copyright 2020 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shttp
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterDatagramHandlerScopedPerMux(t *testing.T) {
+	muxA := http.NewServeMux()
+	muxB := http.NewServeMux()
+
+	var calledA, calledB bool
+	handlerA := DatagramHandlerFunc(func(*DatagramFlow, []byte) { calledA = true })
+	handlerB := DatagramHandlerFunc(func(*DatagramFlow, []byte) { calledB = true })
+
+	RegisterDatagramHandler(muxA, "/datagram", handlerA)
+	RegisterDatagramHandler(muxB, "/datagram", handlerB)
+
+	regA := muxRegistries[muxA]
+	regB := muxRegistries[muxB]
+	if !assert.NotNil(t, regA) || !assert.NotNil(t, regB) {
+		return
+	}
+
+	regA.handlers["/datagram"].ServeDatagram(nil, nil)
+	assert.True(t, calledA)
+	assert.False(t, calledB)
+
+	calledA = false
+	regB.handlers["/datagram"].ServeDatagram(nil, nil)
+	assert.True(t, calledB)
+	assert.False(t, calledA)
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shttp
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/http3"
+
+	"github.com/netsec-ethz/scion-apps/pkg/appnet"
+)
+
+// ListenAndServeSCIONH3 listens for real HTTP/3 connections (as opposed to
+// the HTTP/2-over-QUIC spoken by ListenAndServeSCION) on the SCION address
+// laddr and serves mux. QUIC datagrams (RFC 9221) are enabled on the
+// underlying quic.EarlyListener, so handlers registered via
+// RegisterDatagramHandler on mux can exchange raw datagrams associated with
+// an HTTP/3 request stream.
+func ListenAndServeSCIONH3(laddr, tlsCertFile, tlsKeyFile string, mux *http.ServeMux,
+	opts ...Option) error {
+
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	pconn, _, err := scionPacketConn(laddr)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := quicTLSConfig(tlsCertFile, tlsKeyFile, []string{"h3-scion"})
+	if err != nil {
+		return err
+	}
+	quicConfig := quicServerConfig(true)
+
+	ln, err := quic.ListenEarly(pconn, tlsConfig, quicConfig)
+	if err != nil {
+		return err
+	}
+	server := &http3.Server{
+		Server: &http.Server{
+			Handler:   newRCMgrHandler(withOpenRPCDiscovery(newDatagramDispatcher(mux), o), o.rcmgr),
+			TLSConfig: tlsConfig,
+		},
+		QuicConfig: quicConfig,
+	}
+	return server.ServeListener(ln)
+}
+
+// RoundTripperH3 creates an http.RoundTripper that dials SCION-native HTTP/3
+// connections. laddr is the local SCION address to dial from; leave it
+// empty to let appnet pick one.
+func RoundTripperH3(laddr string) (http.RoundTripper, error) {
+	pconn, _, err := scionPacketConn(laddr)
+	if err != nil {
+		return nil, err
+	}
+	transport := &quic.Transport{Conn: pconn}
+	return &http3.RoundTripper{
+		Dial: func(ctx context.Context, addr string, tlsCfg *tls.Config,
+			cfg *quic.Config) (quic.EarlyConnection, error) {
+
+			raddr, err := appnet.ResolveUDPAddr(addr)
+			if err != nil {
+				return nil, err
+			}
+			return transport.DialEarly(ctx, raddr, tlsCfg, cfg)
+		},
+	}, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shttp
+
+import "net/http"
+
+// Option customizes ListenAndServeSCION / ListenAndServeSCIONH3.
+type Option func(*options)
+
+type options struct {
+	rcmgr          ResourceManager
+	openRPCHandler http.Handler
+	openRPCPath    string
+}
+
+func defaultOptions() *options {
+	return &options{rcmgr: NullResourceManager, openRPCPath: "/rpc.discover"}
+}
+
+// WithResourceManager makes the server consult rm for every HTTP request: a
+// ConnScope per remote address with requests in flight, a StreamScope per
+// request, and a memory reservation for the request's declared body length.
+// QUIC connections themselves are accepted without consulting rm; a
+// rejected request gets a 503 response. Without this option, a
+// server runs with NullResourceManager and accepts everything, same as
+// before ResourceManager existed.
+func WithResourceManager(rm ResourceManager) Option {
+	return func(o *options) {
+		o.rcmgr = rm
+	}
+}
+
+// WithOpenRPCDiscovery serves handler (typically rpcdiscover.Handler(doc))
+// under path, in addition to whatever handler was passed to
+// ListenAndServeSCION / ListenAndServeSCIONH3. path defaults to
+// "/rpc.discover" when empty.
+func WithOpenRPCDiscovery(path string, handler http.Handler) Option {
+	return func(o *options) {
+		if path != "" {
+			o.openRPCPath = path
+		}
+		o.openRPCHandler = handler
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2020 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shttp
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/scionproto/scion/go/lib/addr"
+)
+
+// ErrResourceLimitExceeded is returned by scope reservation methods when
+// granting the reservation would exceed a configured limit.
+var ErrResourceLimitExceeded = errors.New("shttp: resource limit exceeded")
+
+// RemoteEndpoint identifies the remote side of a SCION connection for the
+// purposes of resource accounting.
+type RemoteEndpoint struct {
+	RemoteIA   addr.IA
+	RemoteHost string
+	Protocol   string
+}
+
+// ResourceManager gives a SCION HTTP server a say over which connections,
+// streams and inbound bytes it is willing to accept, scoped per remote AS.
+// Its shape mirrors libp2p's resource manager (ConnManagementScope /
+// StreamManagementScope / PeerScope): a scope is reserved up front and
+// released via Done when the corresponding connection/stream/request
+// completes.
+type ResourceManager interface {
+	// OpenConnection reserves resources for a new QUIC connection from
+	// remote. If it returns an error, the connection must be refused.
+	OpenConnection(remote RemoteEndpoint) (ConnScope, error)
+}
+
+// ConnScope is the PeerScope/ConnManagementScope equivalent: it tracks the
+// resources reserved for a single connection and lets callers open
+// StreamScopes nested under it.
+type ConnScope interface {
+	// OpenStream reserves resources for a new HTTP stream multiplexed on
+	// this connection. If it returns an error, the stream must be refused.
+	OpenStream() (StreamScope, error)
+	// Done releases the connection's reservation.
+	Done()
+}
+
+// StreamScope is the StreamManagementScope equivalent: it tracks the
+// resources reserved for a single HTTP stream.
+type StreamScope interface {
+	// ReserveMemory reserves n additional inbound bytes against the
+	// stream's (and transitively, the connection's and AS's) memory
+	// budget. It returns ErrResourceLimitExceeded if the budget is
+	// exhausted.
+	ReserveMemory(n int) error
+	// Done releases the stream's reservation.
+	Done()
+}
+
+// NullResourceManager is the default ResourceManager: it never rejects a
+// connection, stream or byte reservation, and does not export any metrics.
+// Programs that do not pass the WithResourceManager option to
+// ListenAndServeSCION / ListenAndServeSCIONH3 get this behavior, so the
+// feature is entirely opt-in.
+var NullResourceManager ResourceManager = nullResourceManager{}
+
+type nullResourceManager struct{}
+
+func (nullResourceManager) OpenConnection(RemoteEndpoint) (ConnScope, error) {
+	return nullConnScope{}, nil
+}
+
+type nullConnScope struct{}
+
+func (nullConnScope) OpenStream() (StreamScope, error) { return nullStreamScope{}, nil }
+func (nullConnScope) Done()                            {}
+
+type nullStreamScope struct{}
+
+func (nullStreamScope) ReserveMemory(int) error { return nil }
+func (nullStreamScope) Done()                   {}
+
+// FixedLimits are the per-remote-AS caps enforced by a FixedLimiter.
+type FixedLimits struct {
+	// MaxConns is the maximum number of simultaneously open connections
+	// from a single remote AS. Zero means unlimited.
+	MaxConns int
+	// MaxStreamsPerConn is the maximum number of simultaneously open HTTP
+	// streams per connection. Zero means unlimited.
+	MaxStreamsPerConn int
+	// MaxMemoryBytes is the maximum number of inbound bytes a single
+	// remote AS may have reserved at once. Zero means unlimited.
+	MaxMemoryBytes int
+}
+
+// NewFixedLimiter creates a ResourceManager that enforces cfg uniformly for
+// every remote AS. It is the simplest non-trivial ResourceManager: a single
+// set of caps applied per (RemoteIA) scope, with Prometheus metrics
+// reporting usage and rejections per AS.
+func NewFixedLimiter(cfg FixedLimits) ResourceManager {
+	return &fixedLimiter{
+		cfg:   cfg,
+		asUse: make(map[addr.IA]*asUsage),
+	}
+}
+
+type asUsage struct {
+	conns int
+	bytes int
+}
+
+type fixedLimiter struct {
+	cfg   FixedLimits
+	mu    sync.Mutex
+	asUse map[addr.IA]*asUsage
+}
+
+func (f *fixedLimiter) usage(ia addr.IA) *asUsage {
+	u, ok := f.asUse[ia]
+	if !ok {
+		u = &asUsage{}
+		f.asUse[ia] = u
+	}
+	return u
+}
+
+func (f *fixedLimiter) OpenConnection(remote RemoteEndpoint) (ConnScope, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	u := f.usage(remote.RemoteIA)
+	if f.cfg.MaxConns > 0 && u.conns >= f.cfg.MaxConns {
+		metricLimitRejections.WithLabelValues(remote.RemoteIA.String(), "conn").Inc()
+		return nil, ErrResourceLimitExceeded
+	}
+	u.conns++
+	metricConnsInUse.WithLabelValues(remote.RemoteIA.String()).Set(float64(u.conns))
+	return &fixedConnScope{limiter: f, remote: remote}, nil
+}
+
+type fixedConnScope struct {
+	limiter *fixedLimiter
+	remote  RemoteEndpoint
+
+	mu      sync.Mutex
+	streams int
+}
+
+func (s *fixedConnScope) OpenStream() (StreamScope, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg := s.limiter.cfg
+	if cfg.MaxStreamsPerConn > 0 && s.streams >= cfg.MaxStreamsPerConn {
+		metricLimitRejections.WithLabelValues(s.remote.RemoteIA.String(), "stream").Inc()
+		return nil, ErrResourceLimitExceeded
+	}
+	s.streams++
+	metricStreamsInUse.WithLabelValues(s.remote.RemoteIA.String()).Inc()
+	return &fixedStreamScope{conn: s}, nil
+}
+
+func (s *fixedConnScope) Done() {
+	s.limiter.mu.Lock()
+	defer s.limiter.mu.Unlock()
+	u := s.limiter.usage(s.remote.RemoteIA)
+	u.conns--
+	metricConnsInUse.WithLabelValues(s.remote.RemoteIA.String()).Set(float64(u.conns))
+}
+
+type fixedStreamScope struct {
+	conn     *fixedConnScope
+	reserved int
+}
+
+func (s *fixedStreamScope) ReserveMemory(n int) error {
+	limiter := s.conn.limiter
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	u := limiter.usage(s.conn.remote.RemoteIA)
+	if limiter.cfg.MaxMemoryBytes > 0 && u.bytes+n > limiter.cfg.MaxMemoryBytes {
+		metricLimitRejections.WithLabelValues(s.conn.remote.RemoteIA.String(), "memory").Inc()
+		return ErrResourceLimitExceeded
+	}
+	u.bytes += n
+	s.reserved += n
+	metricBytesInUse.WithLabelValues(s.conn.remote.RemoteIA.String()).Set(float64(u.bytes))
+	return nil
+}
+
+func (s *fixedStreamScope) Done() {
+	s.conn.mu.Lock()
+	s.conn.streams--
+	metricStreamsInUse.WithLabelValues(s.conn.remote.RemoteIA.String()).Dec()
+	s.conn.mu.Unlock()
+
+	limiter := s.conn.limiter
+	limiter.mu.Lock()
+	u := limiter.usage(s.conn.remote.RemoteIA)
+	u.bytes -= s.reserved
+	metricBytesInUse.WithLabelValues(s.conn.remote.RemoteIA.String()).Set(float64(u.bytes))
+	limiter.mu.Unlock()
+}
+
+var (
+	metricConnsInUse = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "shttp",
+		Subsystem: "rcmgr",
+		Name:      "conns_in_use",
+		Help:      "Number of QUIC connections currently open, by remote ISD-AS.",
+	}, []string{"remote_ia"})
+	metricStreamsInUse = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "shttp",
+		Subsystem: "rcmgr",
+		Name:      "streams_in_use",
+		Help:      "Number of HTTP streams currently open, by remote ISD-AS.",
+	}, []string{"remote_ia"})
+	metricBytesInUse = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "shttp",
+		Subsystem: "rcmgr",
+		Name:      "bytes_in_use",
+		Help:      "Inbound bytes currently reserved, by remote ISD-AS.",
+	}, []string{"remote_ia"})
+	metricLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "shttp",
+		Subsystem: "rcmgr",
+		Name:      "limit_rejections_total",
+		Help: "Number of reservations rejected because a limit was exceeded, " +
+			"by remote ISD-AS and scope kind.",
+	}, []string{"remote_ia", "scope"})
+)
+
+func init() {
+	prometheus.MustRegister(metricConnsInUse, metricStreamsInUse, metricBytesInUse,
+		metricLimitRejections)
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shttp
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/scionproto/scion/go/lib/snet"
+)
+
+// rcmgrHandler wraps an http.Handler, reserving a ConnScope per distinct
+// remote address seen (h2quic/http3 hide the raw QUIC session from us, so
+// the remote address is the best available proxy for "connection") and a
+// StreamScope per request, released when the request returns. Connections
+// or streams that fail their reservation are rejected with 503.
+//
+// There is no hook at this layer for "the underlying QUIC connection
+// closed", so a ConnScope's lifetime is approximated by its active request
+// count: the scope is opened on the first request seen from a remote and
+// released (evicting the cache entry) once the last concurrently-active
+// request from that remote finishes. A remote with no in-flight requests
+// holds no reservation between requests.
+type rcmgrHandler struct {
+	next  http.Handler
+	rcmgr ResourceManager
+
+	mu    sync.Mutex
+	conns map[string]*connEntry
+}
+
+// connEntry is the cached ConnScope for a remote host, plus the number of
+// requests currently relying on it.
+type connEntry struct {
+	conn    ConnScope
+	streams int
+}
+
+func newRCMgrHandler(next http.Handler, rcmgr ResourceManager) http.Handler {
+	if _, ok := rcmgr.(nullResourceManager); ok {
+		return next
+	}
+	return &rcmgrHandler{next: next, rcmgr: rcmgr, conns: make(map[string]*connEntry)}
+}
+
+func (h *rcmgrHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	remote := remoteEndpointOf(r)
+
+	entry, err := h.acquireConn(remote)
+	if err != nil {
+		http.Error(w, "connection rejected: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer h.releaseConn(remote.RemoteHost, entry)
+
+	stream, err := entry.conn.OpenStream()
+	if err != nil {
+		http.Error(w, "stream rejected: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer stream.Done()
+
+	if r.ContentLength > 0 {
+		if err := stream.ReserveMemory(int(r.ContentLength)); err != nil {
+			http.Error(w, "request rejected: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	h.next.ServeHTTP(w, r)
+}
+
+// acquireConn returns the cached connEntry for remote, opening a new
+// ConnScope (and caching it) if none is active, and bumps its active
+// request count. Callers must pair every acquireConn with a releaseConn.
+func (h *rcmgrHandler) acquireConn(remote RemoteEndpoint) (*connEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := remote.RemoteHost
+	if entry, ok := h.conns[key]; ok {
+		entry.streams++
+		return entry, nil
+	}
+	conn, err := h.rcmgr.OpenConnection(remote)
+	if err != nil {
+		return nil, err
+	}
+	entry := &connEntry{conn: conn, streams: 1}
+	h.conns[key] = entry
+	return entry, nil
+}
+
+// releaseConn decrements entry's active request count and, once it drops to
+// zero, evicts it from the cache and releases its ConnScope.
+func (h *rcmgrHandler) releaseConn(key string, entry *connEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entry.streams--
+	if entry.streams == 0 {
+		delete(h.conns, key)
+		entry.conn.Done()
+	}
+}
+
+func remoteEndpointOf(r *http.Request) RemoteEndpoint {
+	ep := RemoteEndpoint{RemoteHost: r.RemoteAddr, Protocol: r.Proto}
+	if scionAddr, err := snet.ParseUDPAddr(r.RemoteAddr); err == nil {
+		ep.RemoteIA = scionAddr.IA
+	}
+	return ep
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shttp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+)
+
+func TestFixedLimiterConnDoneReleasesReservation(t *testing.T) {
+	rm := NewFixedLimiter(FixedLimits{MaxConns: 1})
+	remote := RemoteEndpoint{RemoteIA: addr.IA(0x1_ff0000000110)}
+
+	conn, err := rm.OpenConnection(remote)
+	require.NoError(t, err)
+
+	// The single connection slot is in use; a second connection from the
+	// same AS must be rejected.
+	_, err = rm.OpenConnection(remote)
+	assert.ErrorIs(t, err, ErrResourceLimitExceeded)
+
+	conn.Done()
+
+	// Once released, the slot must be available again.
+	conn2, err := rm.OpenConnection(remote)
+	require.NoError(t, err)
+	conn2.Done()
+}
+
+func TestFixedLimiterStreamDoneReleasesReservation(t *testing.T) {
+	rm := NewFixedLimiter(FixedLimits{MaxStreamsPerConn: 1})
+	remote := RemoteEndpoint{RemoteIA: addr.IA(0x1_ff0000000110)}
+
+	conn, err := rm.OpenConnection(remote)
+	require.NoError(t, err)
+	defer conn.Done()
+
+	stream, err := conn.OpenStream()
+	require.NoError(t, err)
+
+	_, err = conn.OpenStream()
+	assert.ErrorIs(t, err, ErrResourceLimitExceeded)
+
+	stream.Done()
+
+	stream2, err := conn.OpenStream()
+	require.NoError(t, err)
+	stream2.Done()
+}
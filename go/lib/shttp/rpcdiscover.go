@@ -0,0 +1,33 @@
+// Copyright 2020 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shttp
+
+import "net/http"
+
+// withOpenRPCDiscovery wraps next so that requests for o.openRPCPath are
+// served by o.openRPCHandler (when WithOpenRPCDiscovery was used) and
+// everything else falls through to next.
+func withOpenRPCDiscovery(next http.Handler, o *options) http.Handler {
+	if o.openRPCHandler == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == o.openRPCPath {
+			o.openRPCHandler.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shttp
+
+import "net/http"
+
+// ListenAndServeSCION listens for SCION-native HTTP connections on laddr and
+// serves handler. It used to speak the pre-standard HTTP/2-over-QUIC
+// protocol via h2quic; that package no longer exists in quic-go, so this is
+// now a thin compatibility wrapper around the same HTTP/3 stack
+// ListenAndServeSCIONH3 uses, keeping the old API working for callers that
+// pass a plain http.Handler rather than a *http.ServeMux. It blocks until
+// the server is shut down or encounters a fatal error. By default every
+// connection/stream/byte is accepted; pass WithResourceManager to bound
+// what a single remote AS may consume.
+func ListenAndServeSCION(laddr, tlsCertFile, tlsKeyFile string, handler http.Handler,
+	opts ...Option) error {
+
+	mux, ok := handler.(*http.ServeMux)
+	if !ok {
+		mux = http.NewServeMux()
+		mux.Handle("/", handler)
+	}
+	return ListenAndServeSCIONH3(laddr, tlsCertFile, tlsKeyFile, mux, opts...)
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shttp provides HTTP-over-SCION/QUIC servers and clients. Serving
+// is built entirely on HTTP/3 with unreliable datagram support
+// (ListenAndServeSCIONH3/RoundTripperH3); ListenAndServeSCION is a thin
+// compatibility wrapper around the same stack for callers migrating from
+// the old HTTP/2-over-QUIC API.
+package shttp
+
+import (
+	"crypto/tls"
+	"net"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/netsec-ethz/scion-apps/pkg/appnet"
+)
+
+// scionPacketConn resolves the local SCION address and opens the
+// appnet.PacketConn that underlies every listener/dialer in this package.
+func scionPacketConn(laddr string) (net.PacketConn, net.Addr, error) {
+	return appnet.ListenPacket(laddr)
+}
+
+// quicTLSConfig returns a tls.Config that advertises nextProtos (ALPN) and
+// is otherwise ready to be handed to quic-go.
+func quicTLSConfig(tlsCertFile, tlsKeyFile string, nextProtos []string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(tlsCertFile, tlsKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   nextProtos,
+	}, nil
+}
+
+// quicServerConfig is the quic-go server configuration used by the HTTP/3
+// listener. EnableDatagrams must be set for ListenAndServeSCIONH3's
+// datagram support (RFC 9221) to work.
+func quicServerConfig(enableDatagrams bool) *quic.Config {
+	return &quic.Config{
+		EnableDatagrams: enableDatagrams,
+		KeepAlive:       true,
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cloudcas implements the go/pkg/cs/trust CACertProvider, PolicyGen
+// and SignerGen interfaces (see mock_trust) on top of Google Cloud's
+// Certificate Authority Service (CAS). Private keys never leave GCP; the
+// control service only ever sees the certificates and signatures produced by
+// remote CreateCertificate RPCs.
+package cloudcas
+
+import (
+	"context"
+	"crypto/x509"
+	"sync"
+	"time"
+
+	privateca "cloud.google.com/go/security/privateca/apiv1"
+	"google.golang.org/api/option"
+	privatecapb "google.golang.org/genproto/googleapis/cloud/security/privateca/v1"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto/cppki"
+	"github.com/scionproto/scion/go/pkg/trust"
+)
+
+// DefaultCACertsTTL is the CA chain cache lifetime used when Backend.CACertsTTL
+// is left at its zero value.
+const DefaultCACertsTTL = 5 * time.Minute
+
+// CAPoolName is the fully qualified resource name of a Cloud CAS CA pool
+// (or single CA), e.g. "projects/my-proj/locations/europe-west1/caPools/my-pool".
+type CAPoolName string
+
+// Backend wraps a privateca.CertificateAuthorityClient and is the shared
+// state behind the CACertProvider, PolicyGen and SignerGen views constructed
+// by NewBackend. All three views cache the same fetched CA chain.
+type Backend struct {
+	// Client is the underlying Cloud CAS client.
+	Client *privateca.CertificateAuthorityClient
+	// Pool is the CA pool (or CA) that issues certificates.
+	Pool CAPoolName
+	// CACertsTTL is how long a fetched CA chain is cached for. If zero,
+	// DefaultCACertsTTL is used.
+	CACertsTTL time.Duration
+
+	mu        sync.Mutex
+	cachedAt  time.Time
+	cachedCAs []*x509.Certificate
+}
+
+// NewBackend creates a Backend talking to Cloud CAS. caPool is the resource
+// name of the CA pool (or CA) to issue certificates from; opts are forwarded
+// to the underlying Cloud CAS client, and are the place to plug in
+// credentials (option.WithCredentialsFile, option.WithTokenSource, ...).
+func NewBackend(ctx context.Context, caPool CAPoolName,
+	opts ...option.ClientOption) (*Backend, error) {
+
+	client, err := privateca.NewCertificateAuthorityClient(ctx, opts...)
+	if err != nil {
+		return nil, common.NewBasicError("creating Cloud CAS client", err)
+	}
+	return &Backend{
+		Client: client,
+		Pool:   caPool,
+	}, nil
+}
+
+// Close releases the resources held by the underlying Cloud CAS client.
+func (b *Backend) Close() error {
+	return b.Client.Close()
+}
+
+// CACerts implements trust.CACertProvider. It returns the active CA
+// certificate chain for the configured pool, fetched from Cloud CAS and
+// cached for CACertsTTL.
+func (b *Backend) CACerts(ctx context.Context) ([]*x509.Certificate, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ttl := b.CACertsTTL
+	if ttl == 0 {
+		ttl = DefaultCACertsTTL
+	}
+	if b.cachedCAs != nil && time.Since(b.cachedAt) < ttl {
+		return b.cachedCAs, nil
+	}
+
+	chain, err := b.fetchCACerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b.cachedCAs = chain
+	b.cachedAt = time.Now()
+	return chain, nil
+}
+
+func (b *Backend) fetchCACerts(ctx context.Context) ([]*x509.Certificate, error) {
+	ca, err := b.Client.GetCertificateAuthority(ctx, &privatecapb.GetCertificateAuthorityRequest{
+		Name: string(b.Pool),
+	})
+	if err != nil {
+		return nil, common.NewBasicError("fetching CA from Cloud CAS", err, "pool", b.Pool)
+	}
+	chain := make([]*x509.Certificate, 0, len(ca.PemCaCertificates))
+	for _, pemCert := range ca.PemCaCertificates {
+		cert, err := parsePEMCertificate([]byte(pemCert))
+		if err != nil {
+			return nil, common.NewBasicError("parsing CA certificate from Cloud CAS", err)
+		}
+		chain = append(chain, cert)
+	}
+	return chain, nil
+}
+
+// PolicyGen implements trust.PolicyGen on top of a Backend.
+type PolicyGen struct {
+	Backend *Backend
+}
+
+// Generate derives a cppki.CAPolicy from the currently active Cloud CAS CA
+// certificate.
+func (g PolicyGen) Generate(ctx context.Context) (cppki.CAPolicy, error) {
+	chain, err := g.Backend.CACerts(ctx)
+	if err != nil {
+		return cppki.CAPolicy{}, err
+	}
+	if len(chain) == 0 {
+		return cppki.CAPolicy{}, common.NewBasicError("no CA certificate available", nil,
+			"pool", g.Backend.Pool)
+	}
+	return cppki.CAPolicy{
+		Validity:    24 * time.Hour,
+		Certificate: chain[0],
+	}, nil
+}
+
+var _ trust.SignerGen = SignerGen{}
+
+// SignerGen implements trust.SignerGen on top of a Backend. The Signer it
+// returns never holds a private key locally; every Sign call is a remote
+// CreateCertificate RPC.
+type SignerGen struct {
+	Backend *Backend
+}
+
+// Generate returns a trust.Signer backed by the Cloud CAS CA.
+func (g SignerGen) Generate(ctx context.Context) (trust.Signer, error) {
+	chain, err := g.Backend.CACerts(ctx)
+	if err != nil {
+		return Signer{}, err
+	}
+	if len(chain) == 0 {
+		return Signer{}, common.NewBasicError("no CA certificate available", nil,
+			"pool", g.Backend.Pool)
+	}
+	return Signer{
+		Backend: g.Backend,
+		Issuer:  chain[0],
+	}, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudcas
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/scionproto/scion/go/lib/scrypto/cppki"
+	"github.com/scionproto/scion/go/pkg/cs/trust/mock_trust"
+	"github.com/scionproto/scion/go/pkg/trust"
+)
+
+// Fake bundles mock_trust-backed CACertProvider, PolicyGen and SignerGen
+// instances that behave like a stubbed-out Cloud CAS, so a control service
+// can be started in tests without talking to GCP.
+type Fake struct {
+	CACertProvider *mock_trust.MockCACertProvider
+	PolicyGen      *mock_trust.MockPolicyGen
+	SignerGen      *mock_trust.MockSignerGen
+}
+
+// NewFake creates a Fake and primes it to always return cas as the CA chain,
+// a cppki.CAPolicy derived from cas[0], and (signer, err) from every
+// SignerGen.Generate call.
+func NewFake(ctrl *gomock.Controller, cas []*x509.Certificate, signer trust.Signer,
+	err error) *Fake {
+
+	f := &Fake{
+		CACertProvider: mock_trust.NewMockCACertProvider(ctrl),
+		PolicyGen:      mock_trust.NewMockPolicyGen(ctrl),
+		SignerGen:      mock_trust.NewMockSignerGen(ctrl),
+	}
+	f.CACertProvider.EXPECT().CACerts(gomock.Any()).Return(cas, nil).AnyTimes()
+	if len(cas) > 0 {
+		f.PolicyGen.EXPECT().Generate(gomock.Any()).
+			Return(cppki.CAPolicy{Certificate: cas[0]}, nil).AnyTimes()
+	}
+	f.SignerGen.EXPECT().Generate(gomock.Any()).Return(signer, err).AnyTimes()
+	return f
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudcas
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+
+	privatecapb "google.golang.org/genproto/googleapis/cloud/security/privateca/v1"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto/x509util"
+	"github.com/scionproto/scion/go/pkg/trust"
+)
+
+var _ trust.Signer = Signer{}
+
+// Signer satisfies the Sign method expected from trust.Signer. It never
+// holds a private key; Sign issues a CreateCertificate RPC against the
+// Cloud CAS CA that owns Issuer and returns the resulting certificate.
+type Signer struct {
+	Backend *Backend
+	// Issuer is the CA certificate that will sign the requested certificate.
+	Issuer *x509.Certificate
+}
+
+// Sign builds a SCION AS certificate signing request for priv's public key
+// from tmpl and has the remote CA sign it. The request carries the subject
+// and SCION-specific extensions of the AS template; tmpl's validity period is
+// not sent, and the CreateCertificate request sets no lifetime. priv never
+// leaves the caller; the private key for Issuer never leaves Cloud CAS.
+func (s Signer) Sign(ctx context.Context, tmpl *x509.Certificate,
+	priv crypto.Signer) (*x509.Certificate, error) {
+
+	asTmpl, err := x509util.ASTemplate(tmpl)
+	if err != nil {
+		return nil, common.NewBasicError("building AS certificate template", err)
+	}
+	csrDER, err := buildCSR(asTmpl, priv)
+	if err != nil {
+		return nil, common.NewBasicError("building certificate request", err)
+	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	resp, err := s.Backend.Client.CreateCertificate(ctx, &privatecapb.CreateCertificateRequest{
+		Parent: string(s.Backend.Pool),
+		Certificate: &privatecapb.Certificate{
+			CertificateConfig: &privatecapb.Certificate_PemCsr{
+				PemCsr: string(csrPEM),
+			},
+		},
+	})
+	if err != nil {
+		return nil, common.NewBasicError("issuing certificate via Cloud CAS", err,
+			"pool", s.Backend.Pool)
+	}
+	cert, err := parsePEMCertificate([]byte(resp.PemCertificate))
+	if err != nil {
+		return nil, common.NewBasicError("parsing certificate issued by Cloud CAS", err)
+	}
+	return cert, nil
+}
+
+// buildCSR builds a DER-encoded PKCS#10 certificate signing request for
+// asTmpl, signed by priv. Factored out of Sign so it can be exercised
+// without a live Cloud CAS backend; most real private keys (RSA, ECDSA)
+// need randomness to sign, so priv.Sign's rand argument must be a real
+// source of entropy, not nil.
+func buildCSR(asTmpl *x509.Certificate, priv crypto.Signer) ([]byte, error) {
+	return x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:            asTmpl.Subject,
+		ExtraExtensions:    asTmpl.ExtraExtensions,
+		SignatureAlgorithm: asTmpl.SignatureAlgorithm,
+	}, priv)
+}
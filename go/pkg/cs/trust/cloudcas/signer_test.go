@@ -0,0 +1,44 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudcas
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCSRUsesRealRandomness(t *testing.T) {
+	// RSA signing needs real randomness (unlike Ed25519); buildCSR must pass
+	// a working rand.Reader through to x509.CreateCertificateRequest, not
+	// nil, or this fails every time.
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		Subject:            pkix.Name{CommonName: "1-ff00:0:110"},
+		SignatureAlgorithm: x509.SHA256WithRSA,
+	}
+	csrDER, err := buildCSR(tmpl, priv)
+	require.NoError(t, err)
+
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	require.NoError(t, err)
+	require.Equal(t, tmpl.Subject.CommonName, csr.Subject.CommonName)
+}
@@ -0,0 +1,30 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudcas
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+func parsePEMCertificate(raw []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, common.NewBasicError("no PEM block found in certificate", nil)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,198 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rpcdiscover generates an OpenRPC 1.x self-description document for
+// a set of Go service interfaces (e.g. the trust-service APIs CACertProvider,
+// PolicyGen, SignerGen) via reflection, and serves it at a configurable path
+// (conventionally /rpc.discover) so tooling can discover a SCION daemon's
+// API surface without out-of-band documentation.
+package rpcdiscover
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// Document is an OpenRPC 1.x document (the subset this package populates).
+type Document struct {
+	OpenRPC string   `json:"openrpc"`
+	Info    Info     `json:"info"`
+	Methods []Method `json:"methods"`
+}
+
+// Info is the OpenRPC "info" object.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Method describes one RPC method.
+type Method struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Params      []ContentDescriptor `json:"params"`
+	Result      ContentDescriptor   `json:"result"`
+}
+
+// ContentDescriptor names and types one parameter or result, per the OpenRPC
+// Content Descriptor Object.
+type ContentDescriptor struct {
+	Name   string     `json:"name"`
+	Schema JSONSchema `json:"schema"`
+}
+
+// JSONSchema is a (heavily simplified) JSON Schema, sufficient to describe
+// the Go types found in practice in the control-plane APIs this package
+// targets.
+type JSONSchema struct {
+	Type        string                `json:"type,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Format      string                `json:"format,omitempty"`
+	Items       *JSONSchema           `json:"items,omitempty"`
+	Properties  map[string]JSONSchema `json:"properties,omitempty"`
+}
+
+// Generator builds a Document by reflecting over the methods of one or more
+// Go interfaces/structs, treating each exported method as an RPC.
+type Generator struct {
+	Title   string
+	Version string
+}
+
+// Generate builds an OpenRPC Document describing every exported method of
+// every value in services. Each value is typically a nil pointer to an
+// interface (e.g. (*trust.CACertProvider)(nil)) or a concrete service
+// implementation; the method's Go parameter and return types are converted
+// to JSON Schema via struct reflection, honoring `description:"..."` struct
+// tags where present.
+func (g Generator) Generate(services ...interface{}) *Document {
+	doc := &Document{
+		OpenRPC: "1.2.6",
+		Info:    Info{Title: g.Title, Version: g.Version},
+	}
+	for _, svc := range services {
+		doc.Methods = append(doc.Methods, methodsOf(svc)...)
+	}
+	return doc
+}
+
+func methodsOf(svc interface{}) []Method {
+	t := reflect.TypeOf(svc)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// reflect.Type.Method includes the receiver as argument 0 of the
+	// method's Type for concrete (non-interface) types, but not for
+	// interface types.
+	skipReceiver := t.Kind() != reflect.Interface
+	var methods []Method
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		methods = append(methods, Method{
+			Name:   t.Name() + "." + m.Name,
+			Params: paramsOf(m.Type, skipReceiver),
+			Result: resultOf(m.Type),
+		})
+	}
+	return methods
+}
+
+func paramsOf(fn reflect.Type, skipReceiver bool) []ContentDescriptor {
+	var params []ContentDescriptor
+	start := 0
+	if skipReceiver {
+		start = 1
+	}
+	for i := start; i < fn.NumIn(); i++ {
+		in := fn.In(i)
+		if in.String() == "context.Context" {
+			continue
+		}
+		params = append(params, ContentDescriptor{
+			Name:   in.Name(),
+			Schema: schemaOf(in),
+		})
+	}
+	return params
+}
+
+func resultOf(fn reflect.Type) ContentDescriptor {
+	for i := 0; i < fn.NumOut(); i++ {
+		out := fn.Out(i)
+		if out.String() == "error" {
+			continue
+		}
+		return ContentDescriptor{Name: "result", Schema: schemaOf(out)}
+	}
+	return ContentDescriptor{}
+}
+
+func schemaOf(t reflect.Type) JSONSchema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem())
+	case reflect.Slice, reflect.Array:
+		elem := schemaOf(t.Elem())
+		return JSONSchema{Type: "array", Items: &elem}
+	case reflect.String:
+		return JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return JSONSchema{Type: "number"}
+	case reflect.Struct:
+		props := make(map[string]JSONSchema, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" { // unexported
+				continue
+			}
+			prop := schemaOf(f.Type)
+			prop.Description = f.Tag.Get("description")
+			props[f.Name] = prop
+		}
+		return JSONSchema{Type: "object", Properties: props}
+	default:
+		return JSONSchema{Format: t.String()}
+	}
+}
+
+// Handler serves doc as application/json. doc is rendered once, on the first
+// request, and the result is cached for all later requests.
+func Handler(doc *Document) http.Handler {
+	var (
+		once     sync.Once
+		rendered []byte
+		err      error
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() {
+			rendered, err = json.Marshal(doc)
+		})
+		if err != nil {
+			http.Error(w, common.NewBasicError("rendering OpenRPC document", err).Error(),
+				http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(rendered)
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcdiscover
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeService interface {
+	Lookup(ctx context.Context, name string) (string, error)
+}
+
+type fakeServiceImpl struct{}
+
+func (fakeServiceImpl) Lookup(ctx context.Context, name string) (string, error) {
+	return name, nil
+}
+
+func TestGenerateSkipsReceiverForConcreteType(t *testing.T) {
+	doc := (Generator{}).Generate(fakeServiceImpl{})
+	require.Len(t, doc.Methods, 1)
+	// Only the string parameter, named after its type (ctx is dropped, and
+	// the receiver must not appear as a bogus extra parameter).
+	assert.Equal(t, []string{"string"}, paramNames(doc.Methods[0].Params))
+}
+
+func TestGenerateInterfaceHasNoReceiver(t *testing.T) {
+	doc := (Generator{}).Generate((*fakeService)(nil))
+	require.Len(t, doc.Methods, 1)
+	assert.Equal(t, []string{"string"}, paramNames(doc.Methods[0].Params))
+}
+
+func paramNames(params []ContentDescriptor) []string {
+	names := make([]string, len(params))
+	for i, p := range params {
+		names[i] = p.Name
+	}
+	return names
+}
@@ -15,6 +15,7 @@
 package proto
 
 import (
+	stdcrypto "crypto"
 	"fmt"
 	"time"
 
@@ -23,6 +24,72 @@ import (
 	"github.com/scionproto/scion/go/lib/util"
 )
 
+// SignatureAlgorithm describes how to produce and check the signature for a
+// given SignType, and the lengths a well-formed key/signature pair must
+// have. PrivKeyLen/PubKeyLen are zero when the algorithm's key has no fixed
+// length (e.g. RSA, where the check is left to the algorithm's own parsing).
+// Registering an entry in signatureAlgorithms is the only thing needed to
+// teach SignS a new algorithm; SignS.Sign/Verify never need to change.
+type SignatureAlgorithm struct {
+	Sign       func(key, msg common.RawBytes) (common.RawBytes, error)
+	Verify     func(key, sig, msg common.RawBytes) error
+	PrivKeyLen int
+	PubKeyLen  int
+	SigLen     int
+	Hash       stdcrypto.Hash
+}
+
+// signatureAlgorithms maps a SignType to the algorithm that implements it.
+// SignType_none is handled separately, since it has no key/signature at all.
+var signatureAlgorithms = map[SignType]SignatureAlgorithm{
+	SignType_ed25519: {
+		Sign: func(key, msg common.RawBytes) (common.RawBytes, error) {
+			return crypto.Sign(msg, key, crypto.Ed25519)
+		},
+		Verify: func(key, sig, msg common.RawBytes) error {
+			return crypto.Verify(msg, sig, key, crypto.Ed25519)
+		},
+		PrivKeyLen: 32,
+		PubKeyLen:  32,
+		SigLen:     64,
+		Hash:       stdcrypto.SHA512,
+	},
+	SignType_ecdsaP256_sha256: {
+		Sign: func(key, msg common.RawBytes) (common.RawBytes, error) {
+			return crypto.Sign(msg, key, crypto.EcdsaP256Sha256)
+		},
+		Verify: func(key, sig, msg common.RawBytes) error {
+			return crypto.Verify(msg, sig, key, crypto.EcdsaP256Sha256)
+		},
+		// 138-byte PKCS#8 DER private key, 91-byte PKIX DER public key.
+		PrivKeyLen: 138,
+		PubKeyLen:  91,
+		Hash:       stdcrypto.SHA256,
+	},
+	SignType_ecdsaP384_sha384: {
+		Sign: func(key, msg common.RawBytes) (common.RawBytes, error) {
+			return crypto.Sign(msg, key, crypto.EcdsaP384Sha384)
+		},
+		Verify: func(key, sig, msg common.RawBytes) error {
+			return crypto.Verify(msg, sig, key, crypto.EcdsaP384Sha384)
+		},
+		// 185-byte PKCS#8 DER private key, 120-byte PKIX DER public key.
+		PrivKeyLen: 185,
+		PubKeyLen:  120,
+		Hash:       stdcrypto.SHA384,
+	},
+	SignType_rsaPSS_sha256: {
+		Sign: func(key, msg common.RawBytes) (common.RawBytes, error) {
+			return crypto.Sign(msg, key, crypto.RsaPssSha256)
+		},
+		Verify: func(key, sig, msg common.RawBytes) error {
+			return crypto.Verify(msg, sig, key, crypto.RsaPssSha256)
+		},
+		SigLen: 256,
+		Hash:   stdcrypto.SHA256,
+	},
+}
+
 var _ Cerealizable = (*SignS)(nil)
 
 type SignS struct {
@@ -52,13 +119,18 @@ func (s *SignS) Copy() *SignS {
 }
 
 func (s *SignS) Sign(key, message common.RawBytes) (common.RawBytes, error) {
-	switch s.Type {
-	case SignType_none:
+	if s.Type == SignType_none {
 		return nil, nil
-	case SignType_ed25519:
-		return crypto.Sign(message, key, crypto.Ed25519)
 	}
-	return nil, common.NewBasicError("SignS.Sign: Unsupported SignType", nil, "type", s.Type)
+	algo, ok := signatureAlgorithms[s.Type]
+	if !ok {
+		return nil, common.NewBasicError("SignS.Sign: Unsupported SignType", nil, "type", s.Type)
+	}
+	if algo.PrivKeyLen != 0 && len(key) != algo.PrivKeyLen {
+		return nil, common.NewBasicError("SignS.Sign: Invalid key length", nil,
+			"type", s.Type, "expected", algo.PrivKeyLen, "actual", len(key))
+	}
+	return algo.Sign(key, message)
 }
 
 func (s *SignS) SignAndSet(key, message common.RawBytes) error {
@@ -69,13 +141,34 @@ func (s *SignS) SignAndSet(key, message common.RawBytes) error {
 }
 
 func (s *SignS) Verify(key, message common.RawBytes) error {
-	switch s.Type {
-	case SignType_none:
+	if s.Type == SignType_none {
 		return nil
-	case SignType_ed25519:
-		return crypto.Verify(message, s.Signature, key, crypto.Ed25519)
 	}
-	return common.NewBasicError("SignS.Verify: Unsupported SignType", nil, "type", s.Type)
+	algo, ok := signatureAlgorithms[s.Type]
+	if !ok {
+		return common.NewBasicError("SignS.Verify: Unsupported SignType", nil, "type", s.Type)
+	}
+	if algo.PubKeyLen != 0 && len(key) != algo.PubKeyLen {
+		return common.NewBasicError("SignS.Verify: Invalid key length", nil,
+			"type", s.Type, "expected", algo.PubKeyLen, "actual", len(key))
+	}
+	if algo.SigLen != 0 && len(s.Signature) != algo.SigLen {
+		return common.NewBasicError("SignS.Verify: Invalid signature length", nil,
+			"type", s.Type, "expected", algo.SigLen, "actual", len(s.Signature))
+	}
+	return algo.Verify(key, s.Signature, message)
+}
+
+// AlgorithmID returns a short identifier of the signature algorithm used by
+// s, suitable for inclusion in log messages.
+func (s *SignS) AlgorithmID() string {
+	if s.Type == SignType_none {
+		return s.Type.String()
+	}
+	if algo, ok := signatureAlgorithms[s.Type]; ok {
+		return fmt.Sprintf("%s/%s", s.Type, algo.Hash)
+	}
+	return s.Type.String()
 }
 
 func (s *SignS) Pack() common.RawBytes {
@@ -0,0 +1,106 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+func TestSignSEd25519RoundTrip(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	msg := common.RawBytes("a message")
+	s := NewSignS(SignType_ed25519, common.RawBytes("src"))
+	require.NoError(t, s.SignAndSet(common.RawBytes(priv.Seed()), msg))
+	assert.NoError(t, s.Verify(common.RawBytes(pub), msg))
+}
+
+func TestSignSEcdsaP256RoundTrip(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+
+	msg := common.RawBytes("a message")
+	s := NewSignS(SignType_ecdsaP256_sha256, common.RawBytes("src"))
+	require.NoError(t, s.SignAndSet(common.RawBytes(privDER), msg))
+	assert.NoError(t, s.Verify(common.RawBytes(pubDER), msg))
+}
+
+func TestSignSEcdsaP384RoundTrip(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+
+	msg := common.RawBytes("a message")
+	s := NewSignS(SignType_ecdsaP384_sha384, common.RawBytes("src"))
+	require.NoError(t, s.SignAndSet(common.RawBytes(privDER), msg))
+	assert.NoError(t, s.Verify(common.RawBytes(pubDER), msg))
+}
+
+func TestSignSRsaPSSRoundTrip(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+
+	msg := common.RawBytes("a message")
+	s := NewSignS(SignType_rsaPSS_sha256, common.RawBytes("src"))
+	require.NoError(t, s.SignAndSet(common.RawBytes(privDER), msg))
+	assert.NoError(t, s.Verify(common.RawBytes(pubDER), msg))
+}
+
+func TestSignSRejectsWrongKeyLength(t *testing.T) {
+	s := NewSignS(SignType_ed25519, common.RawBytes("src"))
+	_, err := s.Sign(common.RawBytes("too short"), common.RawBytes("msg"))
+	assert.Error(t, err)
+}
+
+func TestSignSNoneNeverFails(t *testing.T) {
+	s := NewSignS(SignType_none, common.RawBytes("src"))
+	require.NoError(t, s.SignAndSet(nil, common.RawBytes("msg")))
+	assert.NoError(t, s.Verify(nil, common.RawBytes("msg")))
+}
+
+func TestSignSUnsupportedType(t *testing.T) {
+	s := NewSignS(SignType(255), common.RawBytes("src"))
+	_, err := s.Sign(common.RawBytes("key"), common.RawBytes("msg"))
+	assert.Error(t, err)
+	assert.Error(t, s.Verify(common.RawBytes("key"), common.RawBytes("msg")))
+}
+
+func TestSignSAlgorithmID(t *testing.T) {
+	s := NewSignS(SignType_ed25519, common.RawBytes("src"))
+	assert.Contains(t, s.AlgorithmID(), "SHA-512")
+}
@@ -0,0 +1,27 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proto
+
+// SignType_ecdsaP256_sha256, SignType_ecdsaP384_sha384 and
+// SignType_rsaPSS_sha256 extend the capnp-generated SignType enum
+// (sign.capnp) with the algorithm-agility values used by signatureAlgorithms
+// in sign.go. They are appended after the existing SignType_none/
+// SignType_ed25519 values, so the wire value of every pre-existing SignType
+// is unchanged.
+const (
+	SignType_ecdsaP256_sha256 SignType = iota + 2
+	SignType_ecdsaP384_sha384
+	SignType_rsaPSS_sha256
+)
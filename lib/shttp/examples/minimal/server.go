@@ -20,7 +20,7 @@ import (
 	"log"
 	"net/http"
 
-	"github.com/netsec-ethz/scion-apps/lib/shttp"
+	"github.com/scionproto/scion/go/lib/shttp"
 )
 
 func main() {